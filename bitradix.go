@@ -0,0 +1,209 @@
+package radix
+
+import (
+	"math/bits"
+)
+
+// BitRadix implements a bit-level PATRICIA trie: it branches on individual
+// bits rather than whole bytes, which makes it suitable for keys that are
+// not naturally byte-aligned, such as CIDR prefixes, MAC prefixes or other
+// bitstrings used in longest-prefix-match lookups.
+//
+// Unlike Radix, a BitRadix node has exactly two children, one for each
+// value of the next unmatched bit.
+type BitRadix struct {
+	// bits holds the bits of the edge leading to this node, left-aligned
+	// so that bit 0 of bits is the first bit of the edge.
+	bits   []byte
+	bitlen int
+	parent *BitRadix
+
+	children [2]*BitRadix
+
+	// The contents of the BitRadix node.
+	Value interface{}
+}
+
+// NewBitRadix returns an initialized, empty BitRadix tree.
+func NewBitRadix() *BitRadix {
+	return &BitRadix{}
+}
+
+// bitAt returns the bit at position i (0-indexed from the most significant
+// bit of b[0]).
+func bitAt(b []byte, i int) byte {
+	return (b[i/8] >> uint(7-i%8)) & 1
+}
+
+// commonBitLen returns the number of leading bits that a and b have in
+// common, up to the shorter of alen and blen.
+func commonBitLen(a []byte, alen int, b []byte, blen int) int {
+	n := alen
+	if blen < n {
+		n = blen
+	}
+	nbytes := n / 8
+	for i := 0; i < nbytes; i++ {
+		if a[i] != b[i] {
+			return i*8 + bits.LeadingZeros8(a[i]^b[i])
+		}
+	}
+	for i := nbytes * 8; i < n; i++ {
+		if bitAt(a, i) != bitAt(b, i) {
+			return i
+		}
+	}
+	return n
+}
+
+// sliceBits returns bits [start, end) of src, re-packed into a freshly
+// allocated byte slice so that bit 0 of the result is bit start of src.
+func sliceBits(src []byte, start, end int) []byte {
+	n := end - start
+	if n <= 0 {
+		return nil
+	}
+	out := make([]byte, (n+7)/8)
+	byteShift := start / 8
+	bitShift := uint(start % 8)
+	for i := range out {
+		var cur, next byte
+		if byteShift+i < len(src) {
+			cur = src[byteShift+i]
+		}
+		if byteShift+i+1 < len(src) {
+			next = src[byteShift+i+1]
+		}
+		out[i] = cur<<bitShift | next>>(8-bitShift)
+	}
+	if rem := uint(n % 8); rem != 0 {
+		out[len(out)-1] &^= 0xff >> rem
+	}
+	return out
+}
+
+// copyBits copies the first n bits of src into dst, starting at bit offset
+// dstStart of dst. dst must be large enough to hold dstStart+n bits.
+func copyBits(dst []byte, dstStart int, src []byte, n int) {
+	for i := 0; i < n; i++ {
+		if bitAt(src, i) == 1 {
+			idx := dstStart + i
+			dst[idx/8] |= 1 << uint(7-idx%8)
+		}
+	}
+}
+
+// Bits returns the full bit-string stored under r, and its length in bits,
+// by walking up to the root and concatenating the edge labels.
+func (r *BitRadix) Bits() ([]byte, int) {
+	total := 0
+	for p := r; p != nil && p.bitlen > 0; p = p.parent {
+		total += p.bitlen
+	}
+	out := make([]byte, (total+7)/8)
+	pos := total
+	for p := r; p != nil && p.bitlen > 0; p = p.parent {
+		pos -= p.bitlen
+		copyBits(out, pos, p.bits, p.bitlen)
+	}
+	return out, total
+}
+
+// InsertBits inserts value into the tree under the first keylen bits of
+// key. It returns the node it just inserted. r must be the root of the
+// BitRadix tree.
+func (r *BitRadix) InsertBits(key []byte, keylen int, value interface{}) *BitRadix {
+	return r.insertBits(sliceBits(key, 0, keylen), keylen, value)
+}
+
+func (r *BitRadix) insertBits(key []byte, keylen int, value interface{}) *BitRadix {
+	if keylen == 0 {
+		r.Value = value
+		return r
+	}
+
+	b := bitAt(key, 0)
+	child := r.children[b]
+	if child == nil {
+		leaf := &BitRadix{bits: key, bitlen: keylen, parent: r, Value: value}
+		r.children[b] = leaf
+		return leaf
+	}
+
+	common := commonBitLen(key, keylen, child.bits, child.bitlen)
+
+	if common == child.bitlen {
+		return child.insertBits(sliceBits(key, common, keylen), keylen-common, value)
+	}
+
+	// child's edge is longer than the shared prefix: split it at bit
+	// `common`, inserting a new node to hold the shared part.
+	newChild := &BitRadix{bits: sliceBits(child.bits, 0, common), bitlen: common, parent: r}
+	r.children[b] = newChild
+
+	child.bits = sliceBits(child.bits, common, child.bitlen)
+	child.bitlen -= common
+	child.parent = newChild
+	newChild.children[bitAt(child.bits, 0)] = child
+
+	if common == keylen {
+		newChild.Value = value
+		return newChild
+	}
+
+	rem := sliceBits(key, common, keylen)
+	leaf := &BitRadix{bits: rem, bitlen: keylen - common, parent: newChild, Value: value}
+	newChild.children[bitAt(rem, 0)] = leaf
+	return leaf
+}
+
+// FindBits returns the node holding exactly the first keylen bits of key,
+// and whether that node has a non-nil Value. r must be the root of the
+// BitRadix tree.
+func (r *BitRadix) FindBits(key []byte, keylen int) (*BitRadix, bool) {
+	return r.findBits(sliceBits(key, 0, keylen), keylen)
+}
+
+func (r *BitRadix) findBits(key []byte, keylen int) (*BitRadix, bool) {
+	if keylen == 0 {
+		return r, r.Value != nil
+	}
+	child := r.children[bitAt(key, 0)]
+	if child == nil {
+		return nil, false
+	}
+	common := commonBitLen(key, keylen, child.bits, child.bitlen)
+	if common != child.bitlen {
+		return nil, false
+	}
+	return child.findBits(sliceBits(key, common, keylen), keylen-common)
+}
+
+// LongestPrefixBits returns the node holding the longest stored bitstring
+// that is a prefix of the first keylen bits of key, along with true if one
+// was found. This implements longest-prefix-match lookups such as CIDR
+// routing. r must be the root of the BitRadix tree.
+func (r *BitRadix) LongestPrefixBits(key []byte, keylen int) (*BitRadix, bool) {
+	return r.longestPrefixBits(sliceBits(key, 0, keylen), keylen)
+}
+
+func (r *BitRadix) longestPrefixBits(key []byte, keylen int) (node *BitRadix, found bool) {
+	if r.Value != nil {
+		node, found = r, true
+	}
+	if keylen == 0 {
+		return
+	}
+	child := r.children[bitAt(key, 0)]
+	if child == nil {
+		return
+	}
+	common := commonBitLen(key, keylen, child.bits, child.bitlen)
+	if common != child.bitlen {
+		return
+	}
+	if n, ok := child.longestPrefixBits(sliceBits(key, common, keylen), keylen-common); ok {
+		return n, true
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package radix
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBitRadixInsertFind(t *testing.T) {
+	r := NewBitRadix()
+	r.InsertBits([]byte{0xc0, 0xa8, 0x00, 0x00}, 24, "192.168.0.0/24")
+	r.InsertBits([]byte{0xc0, 0xa8, 0x01, 0x00}, 24, "192.168.1.0/24")
+	r.InsertBits([]byte{0xc0, 0xa8, 0x00, 0x00}, 16, "192.168.0.0/16")
+
+	if n, ok := r.FindBits([]byte{0xc0, 0xa8, 0x00, 0x00}, 24); !ok || n.Value != "192.168.0.0/24" {
+		t.Fatalf("FindBits(192.168.0.0/24) = %v, %v", n, ok)
+	}
+	if n, ok := r.FindBits([]byte{0xc0, 0xa8, 0x01, 0x00}, 24); !ok || n.Value != "192.168.1.0/24" {
+		t.Fatalf("FindBits(192.168.1.0/24) = %v, %v", n, ok)
+	}
+	if _, ok := r.FindBits([]byte{0xc0, 0xa8, 0x02, 0x00}, 24); ok {
+		t.Fatal("FindBits(192.168.2.0/24) should not be found")
+	}
+}
+
+func TestBitRadixLongestPrefix(t *testing.T) {
+	r := NewBitRadix()
+	r.InsertBits([]byte{0xc0, 0xa8, 0x00, 0x00}, 16, "192.168.0.0/16")
+	r.InsertBits([]byte{0xc0, 0xa8, 0x01, 0x00}, 24, "192.168.1.0/24")
+
+	n, ok := r.LongestPrefixBits([]byte{0xc0, 0xa8, 0x01, 0x2a}, 32)
+	if !ok || n.Value != "192.168.1.0/24" {
+		t.Fatalf("LongestPrefixBits(192.168.1.42) = %v, %v", n, ok)
+	}
+
+	n, ok = r.LongestPrefixBits([]byte{0xc0, 0xa8, 0x02, 0x2a}, 32)
+	if !ok || n.Value != "192.168.0.0/16" {
+		t.Fatalf("LongestPrefixBits(192.168.2.42) = %v, %v", n, ok)
+	}
+
+	if _, ok := r.LongestPrefixBits([]byte{0x0a, 0x00, 0x00, 0x01}, 32); ok {
+		t.Fatal("LongestPrefixBits(10.0.0.1) should not be found")
+	}
+}
+
+// TestBitRadixInsertBitsReturnsLeaf checks that InsertBits returns the node
+// actually holding the inserted value, even when the new key diverges from
+// an existing edge partway through, forcing a three-way split.
+func TestBitRadixInsertBitsReturnsLeaf(t *testing.T) {
+	r := NewBitRadix()
+	r.InsertBits([]byte{0x1b}, 4, "v0")
+	n := r.InsertBits([]byte{0x4b}, 4, "v2")
+	if n.Value != "v2" {
+		t.Fatalf("InsertBits returned node with Value %v, want v2", n.Value)
+	}
+}
+
+func TestBitRadixBits(t *testing.T) {
+	r := NewBitRadix()
+	key := []byte{0xc0, 0xa8, 0x01, 0x00}
+	n := r.InsertBits(key, 24, "x")
+	got, gotlen := n.Bits()
+	if gotlen != 24 {
+		t.Fatalf("Bits() length = %d, want 24", gotlen)
+	}
+	want := []byte{0xc0, 0xa8, 0x01}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Bits() = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,224 @@
+package radix
+
+import "sort"
+
+// sparseChildSetThreshold is the largest fanout a node's sparse child
+// container holds; one more child promotes it to a dense one.
+const sparseChildSetThreshold = 8
+
+// childSet holds the children of a Radix node, keyed by the first byte of
+// each child's key. It hides whatever representation is cheapest for the
+// node's current fanout behind get/put/remove/min/max/successor/
+// predecessor/iterate, so that Radix's own methods never need to know
+// whether a node is sparse or dense.
+type childSet interface {
+	get(b byte) (*Radix, bool)
+	// put inserts or overwrites the child at b, returning the childSet to
+	// use from now on (put may promote a sparse set to a dense one).
+	put(b byte, child *Radix) childSet
+	remove(b byte)
+	len() int
+	min() (byte, *Radix, bool)
+	max() (byte, *Radix, bool)
+	// successor returns the entry with the smallest key strictly greater
+	// than b.
+	successor(b byte) (byte, *Radix, bool)
+	// predecessor returns the entry with the largest key strictly smaller
+	// than b.
+	predecessor(b byte) (byte, *Radix, bool)
+	iterate(fn func(b byte, child *Radix))
+	// clone returns a shallow copy of the container itself (its own slice or
+	// array, not the children it holds), so that a caller about to call put
+	// or remove on the copy leaves the original container's entries intact.
+	clone() childSet
+}
+
+// newChildSet returns an empty childSet for a freshly created node.
+func newChildSet() childSet {
+	return &sparseChildSet{}
+}
+
+// childEntry is one entry of a sparseChildSet.
+type childEntry struct {
+	b byte
+	c *Radix
+}
+
+// sparseChildSet keeps children sorted by byte in a slice, so lookups are a
+// binary search and min/max/successor/predecessor are free of map
+// iteration. It is cheap for the common case of small fanout, and promotes
+// to a denseChildSet once it grows past sparseChildSetThreshold entries.
+type sparseChildSet struct {
+	entries []childEntry
+}
+
+func (s *sparseChildSet) search(b byte) int {
+	return sort.Search(len(s.entries), func(i int) bool { return s.entries[i].b >= b })
+}
+
+func (s *sparseChildSet) get(b byte) (*Radix, bool) {
+	i := s.search(b)
+	if i < len(s.entries) && s.entries[i].b == b {
+		return s.entries[i].c, true
+	}
+	return nil, false
+}
+
+func (s *sparseChildSet) put(b byte, child *Radix) childSet {
+	i := s.search(b)
+	if i < len(s.entries) && s.entries[i].b == b {
+		s.entries[i].c = child
+		return s
+	}
+	s.entries = append(s.entries, childEntry{})
+	copy(s.entries[i+1:], s.entries[i:])
+	s.entries[i] = childEntry{b, child}
+	if len(s.entries) > sparseChildSetThreshold {
+		return s.promote()
+	}
+	return s
+}
+
+// promote copies every entry into a denseChildSet.
+func (s *sparseChildSet) promote() childSet {
+	d := &denseChildSet{}
+	for _, e := range s.entries {
+		d.put(e.b, e.c)
+	}
+	return d
+}
+
+func (s *sparseChildSet) remove(b byte) {
+	i := s.search(b)
+	if i < len(s.entries) && s.entries[i].b == b {
+		s.entries = append(s.entries[:i], s.entries[i+1:]...)
+	}
+}
+
+func (s *sparseChildSet) len() int { return len(s.entries) }
+
+func (s *sparseChildSet) min() (byte, *Radix, bool) {
+	if len(s.entries) == 0 {
+		return 0, nil, false
+	}
+	e := s.entries[0]
+	return e.b, e.c, true
+}
+
+func (s *sparseChildSet) max() (byte, *Radix, bool) {
+	if len(s.entries) == 0 {
+		return 0, nil, false
+	}
+	e := s.entries[len(s.entries)-1]
+	return e.b, e.c, true
+}
+
+func (s *sparseChildSet) successor(b byte) (byte, *Radix, bool) {
+	i := sort.Search(len(s.entries), func(i int) bool { return s.entries[i].b > b })
+	if i < len(s.entries) {
+		e := s.entries[i]
+		return e.b, e.c, true
+	}
+	return 0, nil, false
+}
+
+func (s *sparseChildSet) predecessor(b byte) (byte, *Radix, bool) {
+	i := s.search(b)
+	if i > 0 {
+		e := s.entries[i-1]
+		return e.b, e.c, true
+	}
+	return 0, nil, false
+}
+
+func (s *sparseChildSet) iterate(fn func(byte, *Radix)) {
+	for _, e := range s.entries {
+		fn(e.b, e.c)
+	}
+}
+
+func (s *sparseChildSet) clone() childSet {
+	c := &sparseChildSet{entries: make([]childEntry, len(s.entries))}
+	copy(c.entries, s.entries)
+	return c
+}
+
+// denseChildSet is a 256-slot array indexed directly by byte, used once a
+// node's fanout passes sparseChildSetThreshold. get/put/remove are O(1);
+// min/max/successor/predecessor scan at most 256 slots, a constant bound
+// rather than one proportional to the node's own fanout.
+type denseChildSet struct {
+	c     [256]*Radix
+	count int
+}
+
+func (d *denseChildSet) get(b byte) (*Radix, bool) {
+	c := d.c[b]
+	return c, c != nil
+}
+
+func (d *denseChildSet) put(b byte, child *Radix) childSet {
+	if d.c[b] == nil {
+		d.count++
+	}
+	d.c[b] = child
+	return d
+}
+
+func (d *denseChildSet) remove(b byte) {
+	if d.c[b] != nil {
+		d.c[b] = nil
+		d.count--
+	}
+}
+
+func (d *denseChildSet) len() int { return d.count }
+
+func (d *denseChildSet) min() (byte, *Radix, bool) {
+	for i := 0; i < 256; i++ {
+		if d.c[i] != nil {
+			return byte(i), d.c[i], true
+		}
+	}
+	return 0, nil, false
+}
+
+func (d *denseChildSet) max() (byte, *Radix, bool) {
+	for i := 255; i >= 0; i-- {
+		if d.c[i] != nil {
+			return byte(i), d.c[i], true
+		}
+	}
+	return 0, nil, false
+}
+
+func (d *denseChildSet) successor(b byte) (byte, *Radix, bool) {
+	for i := int(b) + 1; i < 256; i++ {
+		if d.c[i] != nil {
+			return byte(i), d.c[i], true
+		}
+	}
+	return 0, nil, false
+}
+
+func (d *denseChildSet) predecessor(b byte) (byte, *Radix, bool) {
+	for i := int(b) - 1; i >= 0; i-- {
+		if d.c[i] != nil {
+			return byte(i), d.c[i], true
+		}
+	}
+	return 0, nil, false
+}
+
+func (d *denseChildSet) iterate(fn func(byte, *Radix)) {
+	for i, c := range d.c {
+		if c != nil {
+			fn(byte(i), c)
+		}
+	}
+}
+
+func (d *denseChildSet) clone() childSet {
+	c := &denseChildSet{c: d.c, count: d.count}
+	return c
+}
@@ -0,0 +1,47 @@
+package radix
+
+import "testing"
+
+func TestChildSetSuccessor(t *testing.T) {
+	s := newChildSet()
+	for _, b := range []byte("acdbef") { // insertion order should not matter
+		s = s.put(b, &Radix{})
+	}
+	if _, _, found := s.successor('f'); found {
+		t.Log("Should be false")
+		t.Fail()
+	}
+	if b, _, found := s.successor('b'); !found || b != 'c' {
+		t.Logf("Should be c (%s)!", string(b))
+		t.Fail()
+	}
+	if b, _, found := s.predecessor('c'); !found || b != 'b' {
+		t.Logf("Should be b (%s)!", string(b))
+		t.Fail()
+	}
+}
+
+// TestChildSetPromotion checks that a childSet keeps working once it grows
+// past sparseChildSetThreshold and promotes itself to a denseChildSet.
+func TestChildSetPromotion(t *testing.T) {
+	s := newChildSet()
+	for i := byte(0); i < sparseChildSetThreshold+4; i++ {
+		s = s.put(i, &Radix{})
+	}
+	if _, ok := s.(*denseChildSet); !ok {
+		t.Fatalf("childSet should have promoted to *denseChildSet, got %T", s)
+	}
+	if s.len() != sparseChildSetThreshold+4 {
+		t.Fatalf("len() = %d, want %d", s.len(), sparseChildSetThreshold+4)
+	}
+	if min, _, ok := s.min(); !ok || min != 0 {
+		t.Fatalf("min() = %d, want 0", min)
+	}
+	if max, _, ok := s.max(); !ok || max != sparseChildSetThreshold+3 {
+		t.Fatalf("max() = %d, want %d", max, sparseChildSetThreshold+3)
+	}
+	s.remove(0)
+	if _, ok := s.get(0); ok {
+		t.Fatal("byte 0 should have been removed")
+	}
+}
@@ -0,0 +1,91 @@
+package radix
+
+import "strings"
+
+// FindFuzzy returns every node with a non-nil Value whose key is within
+// Levenshtein distance maxDist of key. It walks the trie once, carrying a
+// single edit-distance row down each path instead of recomputing one per
+// candidate key: descending into a node computes one new row per byte of
+// the node's own key, and a subtree is pruned as soon as every
+// entry in its row exceeds maxDist, since no key further down it can do
+// better.
+func (r *Radix) FindFuzzy(key string, maxDist int) []*Radix {
+	var out []*Radix
+	row := make([]int, len(key)+1)
+	for i := range row {
+		row[i] = i
+	}
+	r.children.iterate(func(_ byte, child *Radix) {
+		child.fuzzy(key, maxDist, row, &out)
+	})
+	return out
+}
+
+// fuzzy advances prevRow, the edit-distance row for the path down to r's
+// parent, by one row per byte of r.key, then either records r (if it
+// holds a value within maxDist) or prunes r's subtree (if the row can no
+// longer reach maxDist), before recursing into r's children.
+func (r *Radix) fuzzy(key string, maxDist int, prevRow []int, out *[]*Radix) {
+	row := prevRow
+	for i := 0; i < len(r.key); i++ {
+		row = fuzzyNextRow(row, key, r.key[i])
+		if rowMin(row) > maxDist {
+			return
+		}
+	}
+	if r.Value != nil && row[len(key)] <= maxDist {
+		*out = append(*out, r)
+	}
+	r.children.iterate(func(_ byte, child *Radix) {
+		child.fuzzy(key, maxDist, row, out)
+	})
+}
+
+// fuzzyNextRow computes the edit-distance row for path+string(ch) from the
+// row for path, following the usual Levenshtein recurrence.
+func fuzzyNextRow(prev []int, key string, ch byte) []int {
+	row := make([]int, len(prev))
+	row[0] = prev[0] + 1
+	for j := 1; j < len(row); j++ {
+		cost := 1
+		if key[j-1] == ch {
+			cost = 0
+		}
+		row[j] = min3(row[j-1]+1, prev[j]+1, prev[j-1]+cost)
+	}
+	return row
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func rowMin(row []int) int {
+	m := row[0]
+	for _, v := range row[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// FindSubstring returns every node with a non-nil Value whose key contains
+// sub, visited in lexicographic order. It is meant for autocomplete- or
+// filter-style UIs, where the whole tree is small enough to scan.
+func (r *Radix) FindSubstring(sub string) []*Radix {
+	var out []*Radix
+	if r.Value != nil && strings.Contains(r.Key(), sub) {
+		out = append(out, r)
+	}
+	r.children.iterate(func(_ byte, child *Radix) {
+		out = append(out, child.FindSubstring(sub)...)
+	})
+	return out
+}
@@ -0,0 +1,80 @@
+package radix
+
+import (
+	"sort"
+	"testing"
+)
+
+func keysOf(nodes []*Radix) []string {
+	keys := make([]string, len(nodes))
+	for i, n := range nodes {
+		keys[i] = n.Key()
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestFindFuzzy(t *testing.T) {
+	r := New()
+	r.insert("test", "a")
+	r.insert("tester", "a")
+	r.insert("team", "a")
+	r.insert("water", "a")
+
+	got := keysOf(r.FindFuzzy("test", 1))
+	want := []string{"test"}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Fatalf("FindFuzzy(test, 1) = %v, want %v", got, want)
+	}
+
+	got = keysOf(r.FindFuzzy("test", 2))
+	want = []string{"team", "test", "tester"}
+	if len(got) != len(want) {
+		t.Fatalf("FindFuzzy(test, 2) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("FindFuzzy(test, 2) = %v, want %v", got, want)
+		}
+	}
+
+	if got := r.FindFuzzy("xyz", 1); len(got) != 0 {
+		t.Fatalf("FindFuzzy(xyz, 1) = %v, want none", keysOf(got))
+	}
+
+	got = keysOf(r.FindFuzzy("tent", 2))
+	want = []string{"team", "test"}
+	if len(got) != len(want) {
+		t.Fatalf("FindFuzzy(tent, 2) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("FindFuzzy(tent, 2) = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFindSubstring(t *testing.T) {
+	r := New()
+	r.insert("test", "a")
+	r.insert("tester", "a")
+	r.insert("team", "a")
+	r.insert("water", "a")
+
+	got := keysOf(r.FindSubstring("ate"))
+	want := []string{"water"}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Fatalf("FindSubstring(ate) = %v, want %v", got, want)
+	}
+
+	got = keysOf(r.FindSubstring("te"))
+	want = []string{"team", "test", "tester", "water"}
+	if len(got) != len(want) {
+		t.Fatalf("FindSubstring(te) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("FindSubstring(te) = %v, want %v", got, want)
+		}
+	}
+}
@@ -11,6 +11,7 @@ package radix
 
 import (
 	"io"
+	"strings"
 )
 
 var MaxKeySize int
@@ -23,7 +24,7 @@ func readKey(r io.Reader) string {
 	b := make([]byte, MaxKeySize)
 
 	if n, err := r.Read(b); err == nil && n > 0 {
-		return string(b)
+		return string(b[:n])
 	}
 	return ""
 }
@@ -44,62 +45,10 @@ func longestCommonPrefix(key, bar string) (string, int) {
 	return key[:x], x // == bar[:x]
 }
 
-// smallestSuccessor walks the keys of the map and returns the smallest
-// successor for key and true. Or if key is the largest key, it will return
-// false, the value of successor isn't specified in that case.
-// We need this function because a map isn't sorted and for the Next() function
-// we *do* need to sort this.
-func smallestSuccessor(m map[byte]*Radix, key byte) (successor byte, found bool) {
-	guard := 256
-	for k, _ := range m {
-		if k > key && int(k) < guard {
-			guard = int(k)
-			successor = k
-			found = true
-		}
-	}
-	return
-}
-
-// leftMostChild returns the smallest child of the current node.
-func leftMostChild(m map[byte]*Radix) (left byte) {
-	left = 255
-	for k, _ := range m {
-		if k < left {
-			left = k
-		}
-	}
-	return
-}
-
-// largestPredecessor is the opposite of smallestSuccessor.
-func largestPredecessor(m map[byte]*Radix, key byte) (pred byte, found bool) {
-	guard := -1
-	for k, _ := range m {
-		if k < key && int(k) > guard {
-			guard = int(k)
-			pred = k
-			found = true
-		}
-	}
-	return
-}
-
-// rightMostChild returns the largest child of the current node.
-func rightMostChild(m map[byte]*Radix) (right byte) {
-	right = 0
-	for k, _ := range m {
-		if k > right {
-			right = k
-		}
-	}
-	return
-}
-
 // Radix represents a radix tree.
 type Radix struct {
-	// children maps the first letter of each child to the child.
-	children map[byte]*Radix
+	// children holds the first letter of each child mapped to the child.
+	children childSet
 	key      string
 	parent   *Radix // a pointer back to the parent
 
@@ -109,7 +58,7 @@ type Radix struct {
 
 // New returns an initialized radix tree.
 func New() *Radix {
-	return &Radix{make(map[byte]*Radix), "", nil, nil}
+	return &Radix{newChildSet(), "", nil, nil}
 }
 
 func (r *Radix) String() string {
@@ -121,13 +70,13 @@ func (r *Radix) stringHelper(indent string) (s string) {
 	if r.Value == nil {
 		s = indent + "<nil>:"
 	}
-	for i, _ := range r.children {
+	r.children.iterate(func(i byte, _ *Radix) {
 		s += string(i)
-	}
+	})
 	s += "\n"
-	for i, r1 := range r.children {
+	r.children.iterate(func(i byte, r1 *Radix) {
 		s += indent + string(i) + ":" + r1.stringHelper("  "+indent)
-	}
+	})
 	return s
 }
 
@@ -153,10 +102,10 @@ func (r *Radix) Up() *Radix {
 
 func (r *Radix) Insert(reader io.Reader, value interface{}) *Radix {
 	key := readKey(reader)
-	if key != "" {
-        return nil
+	if key == "" {
+		return nil
 	}
-    return r.insert(key, value)
+	return r.insert(key, value)
 }
 
 // Insert inserts the value into the tree with the specified key. It returns the radix node
@@ -165,10 +114,11 @@ func (r *Radix) insert(key string, value interface{}) *Radix {
 
 	// look up the child starting with the same letter as key
 	// if there is no child with the same starting letter, insert a new one
-	child, ok := r.children[key[0]]
+	child, ok := r.children.get(key[0])
 	if !ok {
-		r.children[key[0]] = &Radix{make(map[byte]*Radix), key, r, value}
-		return r.children[key[0]]
+		child = &Radix{newChildSet(), key, r, value}
+		r.children = r.children.put(key[0], child)
+		return child
 	}
 
 	if key == child.key {
@@ -183,16 +133,16 @@ func (r *Radix) insert(key string, value interface{}) *Radix {
 	}
 
 	// create new child node to replace current child
-	newChild := &Radix{make(map[byte]*Radix), commonPrefix, r, nil}
+	newChild := &Radix{newChildSet(), commonPrefix, r, nil}
 
 	// replace child of current node with new child: map first letter of common prefix to new child
-	r.children[commonPrefix[0]] = newChild
+	r.children = r.children.put(commonPrefix[0], newChild)
 
 	// shorten old key to the non-shared part
 	child.key = child.key[prefixEnd:]
 
 	// map old child's new first letter to old child as a child of the new child
-	newChild.children[child.key[0]] = child
+	newChild.children = newChild.children.put(child.key[0], child)
 	child.parent = newChild
 
 	// if there are key left of key, insert them into our new child
@@ -215,7 +165,7 @@ func (r *Radix) insert(key string, value interface{}) *Radix {
 func (r *Radix) Find(reader io.Reader) (node *Radix, exact bool) {
 	key := readKey(reader)
 
-    return r.find(key) 
+    return r.find(key)
 }
 
 func (r *Radix) find(key string) (node *Radix, exact bool) {
@@ -223,7 +173,7 @@ func (r *Radix) find(key string) (node *Radix, exact bool) {
 	if key == "" {
 		return nil, false
 	}
-	child, ok := r.children[key[0]]
+	child, ok := r.children.get(key[0])
 	if !ok {
 		if r.Value != nil {
 			return r, false
@@ -289,7 +239,7 @@ func (r *Radix) findFunc(key string, f func(interface{}) bool) (node *Radix, exa
 		return r, false, true
 	}
 
-	child, ok := r.children[key[0]]
+	child, ok := r.children.get(key[0])
 	if !ok {
 		if r.Value != nil {
 			return r, false, false
@@ -337,6 +287,75 @@ func (r *Radix) findFunc(key string, f func(interface{}) bool) (node *Radix, exa
     return child.findFunc(key[prefixEnd:], f)
 }
 
+// LongestPrefix returns the deepest stored key that is a prefix of key, along
+// with its value. If no stored key is a prefix of key, found is false. r must
+// be the root of the Radix tree.
+func (r *Radix) LongestPrefix(key string) (longest string, value interface{}, found bool) {
+	for {
+		if r.Value != nil {
+			longest, value, found = r.Key(), r.Value, true
+		}
+		if key == "" {
+			return
+		}
+		child, ok := r.children.get(key[0])
+		if !ok || !strings.HasPrefix(key, child.key) {
+			return
+		}
+		key = key[len(child.key):]
+		r = child
+	}
+}
+
+// subtreeForPrefix returns the node that roots the subtree covering every key
+// stored under r that starts with prefix, or nil if no such subtree exists.
+func (r *Radix) subtreeForPrefix(prefix string) *Radix {
+	for {
+		if prefix == "" {
+			return r
+		}
+		child, ok := r.children.get(prefix[0])
+		if !ok {
+			return nil
+		}
+		if strings.HasPrefix(child.key, prefix) {
+			// prefix ends inside (or exactly on) child's key, so child's
+			// subtree covers every key that starts with prefix.
+			return child
+		}
+		if !strings.HasPrefix(prefix, child.key) {
+			return nil
+		}
+		prefix = prefix[len(child.key):]
+		r = child
+	}
+}
+
+// WalkPrefix calls fn for every node with a non-nil Value stored under r whose
+// key starts with prefix, visiting them in lexicographic order. The walk stops
+// as soon as fn returns true. r must be the root of the Radix tree.
+func (r *Radix) WalkPrefix(prefix string, fn func(key string, value interface{}) bool) {
+	if n := r.subtreeForPrefix(prefix); n != nil {
+		n.walk(fn)
+	}
+}
+
+// walk visits r and all of its descendants in lexicographic order, calling fn
+// with the full key and value of every node that has a non-nil Value. It
+// returns true once fn has returned true, so the caller can stop recursing.
+func (r *Radix) walk(fn func(key string, value interface{}) bool) bool {
+	if r.Value != nil && fn(r.Key(), r.Value) {
+		return true
+	}
+	stopped := false
+	r.children.iterate(func(_ byte, child *Radix) {
+		if !stopped && child.walk(fn) {
+			stopped = true
+		}
+	})
+	return stopped
+}
+
 // Next returns the next node in the tree. For non-leaf nodes this is the left most
 // child node. For leaf nodes this is the first neighbor to the right. If no such
 // neighbor is found, it's the first existing neighbor of a parent. This finally
@@ -349,18 +368,17 @@ func (r *Radix) Next() *Radix {
 	if r.parent == nil {
 		// The root node should have one child, which is the
 		// apex of the zone, return that
-		for _, x := range r.children { // only one
+		if _, x, ok := r.children.min(); ok {
 			return x
 		}
 	}
-	switch len(r.children) {
+	switch r.children.len() {
 	case 0: // leaf-node
 		// Look in my parent to get a list of my peers
-		neighbor, found := smallestSuccessor(r.parent.children, r.key[0])
+		_, ret, found := r.parent.children.successor(r.key[0])
 		if found {
-			ret := r.parent.children[neighbor]
 			for ret.Value == nil {
-				ret = ret.children[leftMostChild(ret.children)]
+				_, ret, _ = ret.children.min()
 			}
 			return ret
 		}
@@ -368,13 +386,12 @@ func (r *Radix) Next() *Radix {
 		return r.next()
 	default: // non-leaf node
 		// Skip <nil> value nodes, because those have no data
-		ret := r.children[leftMostChild(r.children)]
+		_, ret, _ := r.children.min()
 		for ret.Value == nil {
-			ret = ret.children[leftMostChild(ret.children)]
+			_, ret, _ = ret.children.min()
 		}
 		return ret
 	}
-	panic("dns: not reached")
 }
 
 // next goes up in the tree to look for nodes with a neighbor.
@@ -385,15 +402,14 @@ func (r *Radix) next() *Radix {
 	if r.parent == nil {
 		// The root node should have one child, which is the
 		// apex of the zone, return that
-		for _, x := range r.children { // only one
+		if _, x, ok := r.children.min(); ok {
 			return x
 		}
 	}
-	neighbor, found := smallestSuccessor(r.parent.children, r.key[0])
+	_, ret, found := r.parent.children.successor(r.key[0])
 	if found {
-		ret := r.parent.children[neighbor]
 		if ret.Value == nil {
-			ret = ret.children[leftMostChild(ret.children)]
+			_, ret, _ = ret.children.min()
 		}
 		return ret
 	}
@@ -409,13 +425,12 @@ func (r *Radix) Prev() *Radix {
 	if r.parent == nil {
 		// The root node should have one child, which is the
 		// apex of the zone, return that
-		for _, x := range r.children { // only one
+		if _, x, ok := r.children.min(); ok {
 			return x
 		}
 	}
-	neighbor, found := largestPredecessor(r.parent.children, r.key[0])
+	_, ret, found := r.parent.children.predecessor(r.key[0])
 	if found {
-		ret := r.parent.children[neighbor]
 		return ret.prev()
 	}
 	// leaf-node, but no left neighbor, go up...
@@ -423,8 +438,8 @@ func (r *Radix) Prev() *Radix {
 	for r.Value == nil {
 		if r.parent == nil {
 			// return largest right leaf node
-			for len(r.children) != 0 {
-				r = r.children[rightMostChild(r.children)]
+			for r.children.len() != 0 {
+				_, r, _ = r.children.max()
 			}
 			return r
 		}
@@ -436,34 +451,197 @@ func (r *Radix) Prev() *Radix {
 // prev does down in the tree and selected the right most child until a leaf
 // node is hit.
 func (r *Radix) prev() *Radix {
-	if len(r.children) == 0 {
+	if r.children.len() == 0 {
 		return r
 	}
-	r = r.children[rightMostChild(r.children)]
+	_, r, _ = r.children.max()
 	return r.prev()
 }
 
+// NextKey returns the successor of key: the smallest stored key that is
+// strictly greater than key. key does not need to be present in the tree.
+// r must be the root of the Radix tree.
+func (r *Radix) NextKey(key string) (string, interface{}, bool) {
+	if n := r.successor(key); n != nil {
+		return n.Key(), n.Value, true
+	}
+	return "", nil, false
+}
+
+// PrevKey returns the predecessor of key: the largest stored key that is
+// strictly smaller than key. key does not need to be present in the tree.
+// r must be the root of the Radix tree.
+func (r *Radix) PrevKey(key string) (string, interface{}, bool) {
+	if n := r.predecessor(key); n != nil {
+		return n.Key(), n.Value, true
+	}
+	return "", nil, false
+}
+
+// successor walks down the edges of r that match key and returns the node
+// holding the smallest stored key that is strictly greater than key, or nil
+// if no such key exists.
+func (r *Radix) successor(key string) *Radix {
+	cur := r
+	for key != "" {
+		child, ok := cur.children.get(key[0])
+		if !ok {
+			return cur.successorSibling(key[0])
+		}
+		if strings.HasPrefix(key, child.key) {
+			key = key[len(child.key):]
+			cur = child
+			continue
+		}
+		if strings.HasPrefix(child.key, key) {
+			// key ends inside child's key, so child and everything below
+			// it sorts after key.
+			return child.leftmostDescendant()
+		}
+		_, i := longestCommonPrefix(key, child.key)
+		if child.key[i] > key[i] {
+			return child.leftmostDescendant()
+		}
+		return cur.successorSibling(key[0])
+	}
+	// key matches cur exactly; the successor is whatever comes right after
+	// cur in sorted order, never cur itself.
+	if d := cur.leftmostChildDescendant(); d != nil {
+		return d
+	}
+	return cur.ascendSuccessor()
+}
+
+// predecessor is the mirror image of successor.
+func (r *Radix) predecessor(key string) *Radix {
+	cur := r
+	for key != "" {
+		child, ok := cur.children.get(key[0])
+		if !ok {
+			return cur.predecessorSibling(key[0])
+		}
+		if strings.HasPrefix(key, child.key) {
+			key = key[len(child.key):]
+			cur = child
+			continue
+		}
+		if strings.HasPrefix(child.key, key) {
+			// key ends inside child's key, so child and everything below
+			// it sorts after key: the predecessor lies outside of child.
+			return cur.predecessorSibling(key[0])
+		}
+		_, i := longestCommonPrefix(key, child.key)
+		if child.key[i] < key[i] {
+			return child.prev()
+		}
+		return cur.predecessorSibling(key[0])
+	}
+	// key matches cur exactly; cur's own descendants all extend cur's key
+	// and therefore sort after it, so the predecessor has to be found by
+	// ascending past cur, exactly like Prev does for an existing leaf.
+	return cur.ascendPredecessor()
+}
+
+// successorSibling looks for the left-most non-nil descendant of the first
+// of r's children sorting after byt. If r has none, it ascends through the
+// parent chain looking for the first ancestor with a sibling sorting after
+// the subtree r came from, stopping once the root is exhausted.
+func (r *Radix) successorSibling(byt byte) *Radix {
+	if _, child, found := r.children.successor(byt); found {
+		return child.leftmostDescendant()
+	}
+	return r.ascendSuccessor()
+}
+
+// ascendSuccessor is the ascending half of successorSibling.
+func (r *Radix) ascendSuccessor() *Radix {
+	for r.parent != nil {
+		if _, sibling, found := r.parent.children.successor(r.key[0]); found {
+			return sibling.leftmostDescendant()
+		}
+		r = r.parent
+	}
+	return nil
+}
+
+// predecessorSibling is the mirror image of successorSibling.
+func (r *Radix) predecessorSibling(byt byte) *Radix {
+	if _, child, found := r.children.predecessor(byt); found {
+		return child.prev()
+	}
+	// r's own key is a proper prefix of the key being looked up, so it
+	// sorts before that key and after everything found further up.
+	if r.Value != nil {
+		return r
+	}
+	return r.ascendPredecessor()
+}
+
+// ascendPredecessor climbs from r looking for a left sibling to descend
+// into, or, failing that, for the nearest ancestor that is itself a stored
+// key; an ancestor's key is always a prefix of r's and so always sorts
+// after any left sibling found further up, which is why, unlike
+// ascendSuccessor, the ancestor's own Value is checked at every step.
+func (r *Radix) ascendPredecessor() *Radix {
+	for r.parent != nil {
+		if _, sibling, found := r.parent.children.predecessor(r.key[0]); found {
+			return sibling.prev()
+		}
+		r = r.parent
+		if r.Value != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// leftmostDescendant returns the node holding the smallest stored key in
+// r's subtree, including r itself, or nil if the subtree is empty.
+func (r *Radix) leftmostDescendant() *Radix {
+	if r.Value != nil {
+		return r
+	}
+	_, child, ok := r.children.min()
+	if !ok {
+		return nil
+	}
+	return child.leftmostDescendant()
+}
+
+// leftmostChildDescendant returns the node holding the smallest stored key
+// strictly below r, or nil if r has no children.
+func (r *Radix) leftmostChildDescendant() *Radix {
+	_, child, ok := r.children.min()
+	if !ok {
+		return nil
+	}
+	return child.leftmostDescendant()
+}
+
 // Remove removes any value set to key. It returns the removed node or nil if the
 // node cannot be found.
 func (r *Radix) Remove(key string) *Radix {
-	child, ok := r.children[key[0]]
+	child, ok := r.children.get(key[0])
 	if !ok {
 		return nil
 	}
 
 	// if the correct end node is found...
 	if key == child.key {
-		switch len(child.children) {
+		switch child.children.len() {
 		case 0:
-			delete(r.children, key[0])
+			r.children.remove(key[0])
 		case 1:
-			for _, subchild := range child.children {
+			child.children.iterate(func(_ byte, subchild *Radix) {
 				// essentially moves the subchild up one level to replace the child we want to delete, while keeping the key of child
 				child.key = child.key + subchild.key
 				child.Value = subchild.Value
 				child.children = subchild.children
 				child.parent = r
-			}
+				child.children.iterate(func(_ byte, grandchild *Radix) {
+					grandchild.parent = child
+				})
+			})
 		default:
 			child.Value = nil
 		}
@@ -486,23 +664,22 @@ func (r *Radix) Do(f func(interface{})) {
 	if r.Value != nil {
 		f(r.Value)
 	}
-	for _, child := range r.children {
+	r.children.iterate(func(_ byte, child *Radix) {
 		child.Do(f)
-	}
+	})
 }
 
 // NextDo traverses the tree r in Next-order and calls function f on each node,
 // f's parameter is be r.Value.
 func (r *Radix) NextDo(f func(interface{})) {
-	if r == nil || len(r.children) == 0 {
+	if r == nil || r.children.len() == 0 {
 		return
 	}
 	if r.parent == nil {
 		// root of the tree descend to the first node
-		for _, x := range r.children { // only one
+		if _, x, ok := r.children.min(); ok {
 			r = x
 		}
-
 	}
 	k := r.Key()
 	f(r.Value)
@@ -516,12 +693,12 @@ func (r *Radix) NextDo(f func(interface{})) {
 // PrevDo traverses the tree r in Prev-order and calls function f on each node,
 // f's parameter is be r.Value.
 func (r *Radix) PrevDo(f func(interface{})) {
-	if r == nil || len(r.children) == 0 {
+	if r == nil || r.children.len() == 0 {
 		return
 	}
 	if r.parent == nil {
 		// root of the tree descend to the first node
-		for _, x := range r.children { // only one
+		if _, x, ok := r.children.min(); ok {
 			r = x
 		}
 	}
@@ -541,9 +718,9 @@ func (r *Radix) Len() int {
 		if r.Value != nil {
 			i++
 		}
-		for _, child := range r.children {
+		r.children.iterate(func(_ byte, child *Radix) {
 			i += child.Len()
-		}
+		})
 	}
 	return i
 }
@@ -12,9 +12,9 @@ func printit(r *Radix, level int) {
 	}
 
 	fmt.Printf("%p '%v' value: '%v' parent %p\n", r, r.key, r.Value, r.parent)
-	for _, child := range r.children {
+	r.children.iterate(func(_ byte, child *Radix) {
 		printit(child, level+1)
-	}
+	})
 }
 
 func radixtree() *Radix {
@@ -29,34 +29,15 @@ func radixtree() *Radix {
 // None, of the childeren must have a prefix incommon with r.key
 func validate(r *Radix) bool {
 	return true
-	for _, child := range r.children {
+	ok := true
+	r.children.iterate(func(_ byte, child *Radix) {
 		_, i := longestCommonPrefix(r.key, child.key)
 		if i != 0 {
-			return false
+			ok = false
 		}
 		validate(child)
-	}
-	return true
-}
-func TestSuccessor(t *testing.T) {
-	a := make(map[byte]*Radix)
-	// fake fill it, this is randomized by Go
-	a['a'] = nil
-	a['b'] = nil
-	a['c'] = nil
-	a['d'] = nil
-	a['e'] = nil
-	a['f'] = nil
-	s, f := smallestSuccessor(a, 'f')
-	if f {
-		t.Logf("Should be false")
-		t.Fail()
-	}
-	s, f = smallestSuccessor(a, 'b')
-	if s != 'c' {
-		t.Logf("Should be c (%s)!", string(s))
-		t.Fail()
-	}
+	})
+	return ok
 }
 
 func TestInsert(t *testing.T) {
@@ -101,6 +82,28 @@ func TestRemove(t *testing.T) {
 	//	r.Find("tester").Remove("test")
 }
 
+// TestRemoveMergeReparentsGrandchildren checks that when Remove folds a
+// node's one surviving child up into its place, the grandchildren kept
+// under that child are reparented onto it rather than left pointing at the
+// discarded node. See TestTxnRemoveMergeReparentsGrandchildren for the
+// equivalent check against the Txn-based Remove.
+func TestRemoveMergeReparentsGrandchildren(t *testing.T) {
+	r := New()
+	r.insert("test", "v1")
+	r.insert("testZZZ", "v2")
+	r.insert("testZZZZZZ", "v3")
+
+	r.Remove("test")
+
+	node, _ := r.find("testZZZZZZ")
+	if node == nil {
+		t.Fatal("testZZZZZZ should still be found after Remove")
+	}
+	if k := node.Key(); k != "testZZZZZZ" {
+		t.Fatalf("find(testZZZZZZ).Key() = %q, want testZZZZZZ", k)
+	}
+}
+
 func TestNext(t *testing.T) {
 	r := New()
 	r.Insert(strings.NewReader("nl.miek"), "xx")
@@ -200,6 +203,104 @@ func TestNextPrevEmpty(t *testing.T) {
 	_ = prev
 }
 
+func TestWalkPrefix(t *testing.T) {
+	r := New()
+	r.insert("test", "a")
+	r.insert("tester", "a")
+	r.insert("testering", "a")
+	r.insert("team", "a")
+	r.insert("te", "a")
+
+	var got []string
+	r.WalkPrefix("test", func(key string, value interface{}) bool {
+		got = append(got, key)
+		return false
+	})
+	want := []string{"test", "tester", "testering"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestLongestPrefix(t *testing.T) {
+	r := New()
+	r.insert("te", "a")
+	r.insert("test", "a")
+	r.insert("tester", "a")
+
+	if k, _, ok := r.LongestPrefix("testering"); !ok || k != "tester" {
+		t.Logf("LongestPrefix(testering) should be tester, got %s %v", k, ok)
+		t.Fail()
+	}
+	if k, _, ok := r.LongestPrefix("te"); !ok || k != "te" {
+		t.Logf("LongestPrefix(te) should be te, got %s %v", k, ok)
+		t.Fail()
+	}
+	if _, _, ok := r.LongestPrefix("water"); ok {
+		t.Log("LongestPrefix(water) should not be found")
+		t.Fail()
+	}
+}
+
+func TestNextPrevKey(t *testing.T) {
+	r := New()
+	r.insert("nl.miek", "xx")
+	r.insert("nl.miek.a", "xx")
+	r.insert("nl.miek.c", "xx")
+	r.insert("nl.miek.d", "xx")
+	r.insert("nl.miek.c.a", "xx")
+	r.insert("nl.miek.c.c", "xx")
+
+	next := map[string]string{
+		"nl.miek":     "nl.miek.a",
+		"nl.miek.a":   "nl.miek.c",
+		"nl.miek.b":   "nl.miek.c",
+		"nl.miek.c.a": "nl.miek.c.c",
+		"nl.mieka":    "",
+	}
+	for x, want := range next {
+		k, _, ok := r.NextKey(x)
+		if want == "" {
+			if ok {
+				t.Logf("NextKey(%s) should not be found, got %s", x, k)
+				t.Fail()
+			}
+			continue
+		}
+		if !ok || k != want {
+			t.Logf("NextKey(%s) must be %s, is %s", x, want, k)
+			t.Fail()
+		}
+	}
+
+	prev := map[string]string{
+		"nl.miek.a":   "nl.miek",
+		"nl.miek.c":   "nl.miek.a",
+		"nl.miek.c.c": "nl.miek.c.a",
+		"nl.miek.d":   "nl.miek.c.c",
+		"nl.miek":     "",
+	}
+	for x, want := range prev {
+		k, _, ok := r.PrevKey(x)
+		if want == "" {
+			if ok {
+				t.Logf("PrevKey(%s) should not be found, got %s", x, k)
+				t.Fail()
+			}
+			continue
+		}
+		if !ok || k != want {
+			t.Logf("PrevKey(%s) must be %s, is %s", x, want, k)
+			t.Fail()
+		}
+	}
+}
+
 func ExampleFind() {
 	r := New()
 
@@ -219,10 +320,10 @@ func iter(r *Radix) {
 	if r.Key() != "" {
 		fmt.Printf("prefix %s\n", r.Key())
 	}
-	//	fmt.Println(len(r.children))
-	for _, child := range r.children {
+	//	fmt.Println(r.children.len())
+	r.children.iterate(func(_ byte, child *Radix) {
 		iter(child)
-	}
+	})
 }
 
 func BenchmarkFind(b *testing.B) {
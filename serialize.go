@@ -0,0 +1,216 @@
+package radix
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"io"
+	"strings"
+)
+
+// Encoder turns a node's Value into bytes for on-disk storage.
+type Encoder func(value interface{}) ([]byte, error)
+
+// Decoder turns bytes read back from disk into a node's Value.
+type Decoder func(data []byte) (interface{}, error)
+
+func gobEncode(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode(data []byte) (interface{}, error) {
+	var value interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// MarshalBinary encodes the whole tree rooted at r, using gob to encode
+// each node's Value, so that r satisfies encoding.BinaryMarshaler. Callers
+// that store other concrete types in Value must gob.Register them first.
+func (r *Radix) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.SaveFunc(&buf, gobEncode); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces r with the tree encoded by MarshalBinary, so
+// that r satisfies encoding.BinaryUnmarshaler. r need not be empty; its
+// previous contents are discarded.
+func (r *Radix) UnmarshalBinary(data []byte) error {
+	root, err := LoadFunc(bytes.NewReader(data), gobDecode)
+	if err != nil {
+		return err
+	}
+	*r = *root
+	r.children.iterate(func(_ byte, child *Radix) { child.parent = r })
+	return nil
+}
+
+// Save writes the whole tree rooted at r to w, using gob to encode each
+// node's Value. Use SaveFunc to supply a different encoder.
+func (r *Radix) Save(w io.Writer) error {
+	return r.SaveFunc(w, gobEncode)
+}
+
+// SaveFunc writes the whole tree rooted at r to w, encoding each node's
+// Value with encode. Each node is written as a varint key length, the key
+// bytes, a has-value flag byte, the varint-length-prefixed encoded value
+// (if the flag is set), a varint child count, and then the children
+// themselves in the same format, in ascending byte order.
+func (r *Radix) SaveFunc(w io.Writer, encode Encoder) error {
+	bw := bufio.NewWriter(w)
+	if err := r.save(bw, encode); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (r *Radix) save(w *bufio.Writer, encode Encoder) error {
+	if err := writeBytes(w, []byte(r.key)); err != nil {
+		return err
+	}
+	if r.Value == nil {
+		if err := w.WriteByte(0); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteByte(1); err != nil {
+			return err
+		}
+		data, err := encode(r.Value)
+		if err != nil {
+			return err
+		}
+		if err := writeBytes(w, data); err != nil {
+			return err
+		}
+	}
+	if err := writeUvarint(w, uint64(r.children.len())); err != nil {
+		return err
+	}
+	var err error
+	r.children.iterate(func(_ byte, child *Radix) {
+		if err == nil {
+			err = child.save(w, encode)
+		}
+	})
+	return err
+}
+
+// Load reads a tree written by Save (or SaveFunc with gobEncode) from
+// reader and returns its root.
+func Load(reader io.Reader) (*Radix, error) {
+	return LoadFunc(reader, gobDecode)
+}
+
+// LoadFunc reads a tree written by SaveFunc(w, encode) from reader,
+// decoding each node's Value with decode, and returns its root.
+func LoadFunc(reader io.Reader, decode Decoder) (*Radix, error) {
+	return load(bufio.NewReader(reader), decode)
+}
+
+func load(r *bufio.Reader, decode Decoder) (*Radix, error) {
+	key, err := readBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	hasValue, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	n := &Radix{children: newChildSet(), key: string(key)}
+	if hasValue == 1 {
+		data, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		n.Value, err = decode(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	count, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < count; i++ {
+		child, err := load(r, decode)
+		if err != nil {
+			return nil, err
+		}
+		child.parent = n
+		n.children = n.children.put(child.key[0], child)
+	}
+	return n, nil
+}
+
+func writeUvarint(w *bufio.Writer, v uint64) error {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+func writeBytes(w *bufio.Writer, b []byte) error {
+	if err := writeUvarint(w, uint64(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readUvarint(r *bufio.Reader) (uint64, error) {
+	return binary.ReadUvarint(r)
+}
+
+func readBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Pairs is a push-style iterator over key/value pairs in strictly
+// increasing key order, matching the shape of the standard library's
+// iter.Seq2[string, interface{}] so that, on a Go toolchain new enough to
+// range over it, callers can write `for key, value := range pairs`.
+type Pairs func(yield func(key string, value interface{}) bool)
+
+// LoadBulk inserts every pair of sortedPairs into r, which must already be
+// a Radix root, and returns r. Because sortedPairs is in increasing key
+// order, LoadBulk walks up from the node it inserted last rather than
+// descending from the root for every key: the common prefix between two
+// consecutive sorted keys rarely climbs far, so a bulk load runs close to
+// O(N) instead of the O(N·H) cost of repeated Inserts from the root.
+func (r *Radix) LoadBulk(sortedPairs Pairs) *Radix {
+	cursor := r
+	sortedPairs(func(key string, value interface{}) bool {
+		for cursor.parent != nil && !strings.HasPrefix(key, cursor.Key()) {
+			cursor = cursor.parent
+		}
+		if key == cursor.Key() {
+			// a duplicate of the previous key: overwrite rather than
+			// recurse into insert with an empty suffix, which would index
+			// past the end of it.
+			cursor.Value = value
+			return true
+		}
+		cursor = cursor.insert(key[len(cursor.Key()):], value)
+		return true
+	})
+	return r
+}
@@ -0,0 +1,136 @@
+package radix
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+)
+
+func collectPairs(r *Radix) map[string]interface{} {
+	got := make(map[string]interface{})
+	r.walk(func(key string, value interface{}) bool {
+		got[key] = value
+		return false
+	})
+	return got
+}
+
+func TestSaveLoad(t *testing.T) {
+	r := New()
+	r.insert("test", "a")
+	r.insert("tester", "b")
+	r.insert("team", "c")
+
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	r2, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	got, want := collectPairs(r2), collectPairs(r)
+	if len(got) != len(want) {
+		t.Fatalf("Load roundtrip = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("Load roundtrip[%s] = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestMarshalUnmarshalBinary(t *testing.T) {
+	r := New()
+	r.insert("test", "a")
+	r.insert("tester", "b")
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	r2 := New()
+	r2.insert("stale", "x")
+	if err := r2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+
+	got, want := collectPairs(r2), collectPairs(r)
+	if len(got) != len(want) {
+		t.Fatalf("UnmarshalBinary roundtrip = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("UnmarshalBinary roundtrip[%s] = %v, want %v", k, got[k], v)
+		}
+	}
+	if n, _, ok := r2.LongestPrefix("tester"); !ok || n != "tester" {
+		t.Fatalf("LongestPrefix(tester) = %s %v, want tester true", n, ok)
+	}
+}
+
+func TestLoadBulk(t *testing.T) {
+	values := map[string]interface{}{
+		"team":      "a",
+		"test":      "b",
+		"tester":    "c",
+		"testering": "d",
+		"water":     "e",
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	r := New()
+	r.LoadBulk(func(yield func(string, interface{}) bool) {
+		for _, k := range keys {
+			if !yield(k, values[k]) {
+				return
+			}
+		}
+	})
+
+	got := collectPairs(r)
+	if len(got) != len(values) {
+		t.Fatalf("LoadBulk = %v, want %v", got, values)
+	}
+	for k, v := range values {
+		if got[k] != v {
+			t.Fatalf("LoadBulk[%s] = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+// TestLoadBulkDuplicateKey checks that a duplicate of the previous key
+// overwrites its Value instead of panicking by recursing into insert with
+// an empty suffix.
+func TestLoadBulkDuplicateKey(t *testing.T) {
+	r := New()
+	r.LoadBulk(func(yield func(string, interface{}) bool) {
+		pairs := []struct {
+			key   string
+			value interface{}
+		}{
+			{"test", "a"},
+			{"test", "b"},
+			{"tester", "c"},
+		}
+		for _, p := range pairs {
+			if !yield(p.key, p.value) {
+				return
+			}
+		}
+	})
+
+	if k, v, ok := r.LongestPrefix("test"); !ok || k != "test" || v != "b" {
+		t.Fatalf("LongestPrefix(test) = %s %v %v, want test b true", k, v, ok)
+	}
+	if k, v, ok := r.LongestPrefix("tester"); !ok || k != "tester" || v != "c" {
+		t.Fatalf("LongestPrefix(tester) = %s %v %v, want tester c true", k, v, ok)
+	}
+}
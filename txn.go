@@ -0,0 +1,147 @@
+package radix
+
+// Txn is a transaction against a Radix tree. It lazily clones a node the
+// first time the transaction writes to it, tracking already-cloned nodes so
+// that multiple writes during the same transaction never clone the same
+// node twice, and leaves every subtree it never touches shared, byte for
+// byte, with the tree the transaction was opened against. A reader still
+// holding the original root sees a stable snapshot of the tree for as long
+// as it likes, with no locking, because nothing reachable from that root is
+// ever mutated in place.
+//
+// A node that Txn never clones keeps its original parent pointer, which
+// still points into the snapshot the transaction started from rather than
+// into the tree Commit eventually returns. Key, Up, Next and Prev are safe
+// to call on such a node as long as the traversal stays inside that
+// untouched subtree, since its ancestors are themselves immutable and never
+// rewritten; walking back out into a cloned ancestor is not supported.
+type Txn struct {
+	root   *Radix
+	cloned map[*Radix]bool
+}
+
+// Txn starts a new transaction against r. r must be the root of the Radix
+// tree. r itself, and everything reachable from it, is left untouched: all
+// of the transaction's writes land in cloned nodes, visible only once
+// Commit returns the new root.
+func (r *Radix) Txn() *Txn {
+	return &Txn{root: r, cloned: make(map[*Radix]bool)}
+}
+
+// clone returns a copy of n that this transaction is free to mutate: n
+// itself, if this transaction cloned it already, otherwise a shallow copy
+// that from now on is.
+func (t *Txn) clone(n *Radix) *Radix {
+	if t.cloned[n] {
+		return n
+	}
+	c := &Radix{children: n.children.clone(), key: n.key, parent: n.parent, Value: n.Value}
+	t.cloned[c] = true
+	return c
+}
+
+// Insert inserts value under key, following the same splitting rules as
+// (*Radix).Insert, but cloning every node on the path from the
+// transaction's root down to the insertion point instead of mutating them
+// in place. It returns the node that now holds value.
+func (t *Txn) Insert(key string, value interface{}) *Radix {
+	t.root = t.clone(t.root)
+	return t.insert(t.root, key, value)
+}
+
+func (t *Txn) insert(r *Radix, key string, value interface{}) *Radix {
+	child, ok := r.children.get(key[0])
+	if !ok {
+		leaf := &Radix{children: newChildSet(), key: key, parent: r, Value: value}
+		t.cloned[leaf] = true
+		r.children = r.children.put(key[0], leaf)
+		return leaf
+	}
+	child = t.clone(child)
+	child.parent = r
+	r.children = r.children.put(key[0], child)
+
+	if key == child.key {
+		child.Value = value
+		return child
+	}
+
+	commonPrefix, prefixEnd := longestCommonPrefix(key, child.key)
+
+	if commonPrefix == child.key {
+		return t.insert(child, key[prefixEnd:], value)
+	}
+
+	newChild := &Radix{children: newChildSet(), key: commonPrefix, parent: r}
+	t.cloned[newChild] = true
+	r.children = r.children.put(commonPrefix[0], newChild)
+
+	child.key = child.key[prefixEnd:]
+	child.parent = newChild
+	newChild.children = newChild.children.put(child.key[0], child)
+
+	if key != newChild.key {
+		return t.insert(newChild, key[prefixEnd:], value)
+	}
+	newChild.Value = value
+	return newChild
+}
+
+// Remove removes any value stored at key, following the same rules as
+// (*Radix).Remove, but cloning every node on the path down to it instead of
+// mutating them in place. It returns the removed node, or nil if key was
+// not present.
+func (t *Txn) Remove(key string) *Radix {
+	t.root = t.clone(t.root)
+	return t.remove(t.root, key)
+}
+
+func (t *Txn) remove(r *Radix, key string) *Radix {
+	child, ok := r.children.get(key[0])
+	if !ok {
+		return nil
+	}
+	child = t.clone(child)
+	child.parent = r
+	r.children = r.children.put(key[0], child)
+
+	if key == child.key {
+		switch child.children.len() {
+		case 0:
+			r.children.remove(key[0])
+		case 1:
+			child.children.iterate(func(_ byte, subchild *Radix) {
+				subchild = t.clone(subchild)
+				child.key = child.key + subchild.key
+				child.Value = subchild.Value
+				child.parent = r
+				// subchild.children still holds grandchildren whose parent
+				// points at subchild itself; clone each of them too so that
+				// their parent can be repointed at child without mutating a
+				// node some other snapshot may still be reading.
+				grandchildren := newChildSet()
+				subchild.children.iterate(func(b byte, grandchild *Radix) {
+					grandchild = t.clone(grandchild)
+					grandchild.parent = child
+					grandchildren = grandchildren.put(b, grandchild)
+				})
+				child.children = grandchildren
+			})
+		default:
+			child.Value = nil
+		}
+		return child
+	}
+
+	commonPrefix, prefixEnd := longestCommonPrefix(key, child.key)
+	if child.key != commonPrefix {
+		return nil
+	}
+	return t.remove(child, key[prefixEnd:])
+}
+
+// Commit finalizes the transaction and returns its new root. The
+// transaction must not be used again afterwards.
+func (t *Txn) Commit() *Radix {
+	return t.root
+}
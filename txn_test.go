@@ -0,0 +1,94 @@
+package radix
+
+import "testing"
+
+func TestTxnInsertSnapshotIsolation(t *testing.T) {
+	r := New()
+	r.insert("test", "a")
+	r.insert("team", "a")
+
+	txn := r.Txn()
+	txn.Insert("tester", "b")
+	r2 := txn.Commit()
+
+	if k, _, ok := r.LongestPrefix("tester"); ok && k == "tester" {
+		t.Fatal("original root should not see the write made through Txn")
+	}
+	if k, _, ok := r2.LongestPrefix("tester"); !ok || k != "tester" {
+		t.Fatalf("committed root should contain tester, got %s %v", k, ok)
+	}
+	if k, _, ok := r2.LongestPrefix("test"); !ok || k != "test" {
+		t.Fatalf("committed root should still contain test, got %s %v", k, ok)
+	}
+}
+
+func TestTxnUntouchedSubtreeShared(t *testing.T) {
+	r := New()
+	r.insert("test", "a")
+	r.insert("slow", "a")
+
+	testNode, _ := r.children.get('t')
+	slowNode, _ := r.children.get('s')
+
+	txn := r.Txn()
+	txn.Insert("tester", "b")
+	r2 := txn.Commit()
+
+	newSlow, _ := r2.children.get('s')
+	if newSlow != slowNode {
+		t.Fatal("subtree untouched by the transaction should be shared with the original tree")
+	}
+	newTest, _ := r2.children.get('t')
+	if newTest == testNode {
+		t.Fatal("subtree on the write path should have been cloned, not shared")
+	}
+}
+
+func TestTxnRemove(t *testing.T) {
+	r := New()
+	r.insert("test", "a")
+	r.insert("tester", "b")
+
+	txn := r.Txn()
+	removed := txn.Remove("tester")
+	if removed == nil || removed.Value != "b" {
+		t.Fatalf("Remove(tester) = %v, want node with value b", removed)
+	}
+	r2 := txn.Commit()
+
+	if _, _, ok := r.LongestPrefix("tester"); !ok {
+		t.Fatal("original root should still contain tester")
+	}
+	if k, _, ok := r2.LongestPrefix("tester"); ok && k == "tester" {
+		t.Fatal("committed root should no longer contain tester")
+	}
+}
+
+// TestTxnRemoveMergeReparentsGrandchildren checks that when removing a node
+// folds its one surviving child up into its place, the grandchildren kept
+// under that child get reparented onto it instead of onto the discarded
+// node, so traversal after Commit never wanders back into the pre-removal
+// snapshot.
+func TestTxnRemoveMergeReparentsGrandchildren(t *testing.T) {
+	r := New()
+	r.insert("test", "v1")
+	r.insert("testZZZ", "v2")
+	r.insert("testZZZZZZ", "v3")
+
+	txn := r.Txn()
+	txn.Remove("test")
+	r2 := txn.Commit()
+
+	r2.insert("testZZZA", "v4")
+
+	node, _ := r2.find("testZZZZZZ")
+	if node == nil {
+		t.Fatal("testZZZZZZ should still be found after commit")
+	}
+	if n := node.Next(); n.Key() == "test" {
+		t.Fatalf("Next() walked back into the discarded pre-removal node, got key %q value %v", n.Key(), n.Value)
+	}
+	if u := node.Up(); u != nil && u.Key() == "test" {
+		t.Fatalf("Up() walked back into the discarded pre-removal node, got key %q value %v", u.Key(), u.Value)
+	}
+}